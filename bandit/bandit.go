@@ -0,0 +1,131 @@
+// Package bandit implements a simple epsilon-greedy multi-armed bandit over
+// (Strategy, Mutator, Guider) combinations, so a fuzzing run can learn which
+// combination is currently paying off instead of committing to one for the
+// whole run.
+package bandit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Arm is one registered (Strategy, Mutator, Guider) combination. Strategy,
+// Mutator and Guider are kept as interface{} so this package stays decoupled
+// from the concrete fuzzer types; callers type-assert back to their own
+// Strategy/Mutator/Guider interfaces when a pull is selected.
+type Arm struct {
+	Name     string
+	Strategy interface{}
+	Mutator  interface{}
+	Guider   interface{}
+
+	Pulls      int     `json:"pulls"`
+	MeanReward float64 `json:"meanReward"`
+	EMARate    float64 `json:"emaNewSignalPerSec"`
+}
+
+// emaAlpha controls how quickly the per-arm new-signal-per-second estimate
+// adapts to recent pulls versus older ones.
+const emaAlpha = 0.3
+
+// Scheduler is a plain epsilon-greedy MAB over a fixed set of Arms.
+type Scheduler struct {
+	mu      sync.Mutex
+	epsilon float64
+	arms    []*Arm
+	rng     *rand.Rand
+}
+
+// NewScheduler builds a Scheduler over arms, exploring uniformly at random
+// with probability epsilon and otherwise exploiting the arm with the
+// highest mean reward seen so far.
+func NewScheduler(epsilon float64, arms []*Arm) *Scheduler {
+	return &Scheduler{
+		epsilon: epsilon,
+		arms:    arms,
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Pick selects the next arm to pull.
+func (s *Scheduler) Pick() *Arm {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.arms) == 0 {
+		return nil
+	}
+	if s.rng.Float64() < s.epsilon {
+		return s.arms[s.rng.Intn(len(s.arms))]
+	}
+	var best *Arm
+	for _, a := range s.arms {
+		if a.Pulls == 0 {
+			return a
+		}
+		if best == nil || a.MeanReward > best.MeanReward {
+			best = a
+		}
+	}
+	return best
+}
+
+// Update records the outcome of pulling arm: gain is the number of new
+// coverage points / unique TLC states / unique trace hashes the episode
+// added, and elapsed is the episode's wall-clock duration. The arm's
+// running mean reward and exponential moving average of new-signal-per-second
+// are both updated.
+func (s *Scheduler) Update(arm *Arm, gain int, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reward := 0.0
+	if elapsed > 0 {
+		reward = float64(gain) / elapsed.Seconds()
+	}
+
+	arm.Pulls++
+	arm.MeanReward += (reward - arm.MeanReward) / float64(arm.Pulls)
+	if arm.Pulls == 1 {
+		arm.EMARate = reward
+	} else {
+		arm.EMARate = emaAlpha*reward + (1-emaAlpha)*arm.EMARate
+	}
+}
+
+// Arms returns the registered arms, for reporting and persistence.
+func (s *Scheduler) Arms() []*Arm {
+	return s.arms
+}
+
+// Save writes per-arm pull counts, mean rewards and EMA new-signal-per-second
+// to path as JSON, so arm selection curves can be compared across runs.
+func (s *Scheduler) Save(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(s.arms, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ParseArmSpec parses a repeated --bandit-arm flag value of the form
+// "name=strategy:mutator:guider" into its components.
+func ParseArmSpec(spec string) (name, strategy, mutator, guider string, err error) {
+	nameAndRest := strings.SplitN(spec, "=", 2)
+	if len(nameAndRest) != 2 {
+		return "", "", "", "", fmt.Errorf("bandit: invalid arm spec %q, want name=strategy:mutator:guider", spec)
+	}
+	parts := strings.Split(nameAndRest[1], ":")
+	if len(parts) != 3 {
+		return "", "", "", "", fmt.Errorf("bandit: invalid arm spec %q, want name=strategy:mutator:guider", spec)
+	}
+	return nameAndRest[0], parts[0], parts[1], parts[2], nil
+}
@@ -0,0 +1,68 @@
+package bandit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseArmSpec(t *testing.T) {
+	name, strategy, mutator, guider, err := ParseArmSpec("traceCov=random:combined:trace")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "traceCov" || strategy != "random" || mutator != "combined" || guider != "trace" {
+		t.Fatalf("got (%q, %q, %q, %q)", name, strategy, mutator, guider)
+	}
+
+	for _, bad := range []string{"", "noequals", "name=onlytwo:parts", "name=too:many:parts:here"} {
+		if _, _, _, _, err := ParseArmSpec(bad); err == nil {
+			t.Errorf("ParseArmSpec(%q): expected error, got nil", bad)
+		}
+	}
+}
+
+func TestPickForceExploresUntriedArms(t *testing.T) {
+	// Arm A has never been pulled, B and C have. On pure exploitation (no
+	// random exploration), A must still be forced even though it's at index
+	// 0 and B has a higher mean reward.
+	a := &Arm{Name: "A"}
+	b := &Arm{Name: "B", Pulls: 5, MeanReward: 5}
+	c := &Arm{Name: "C", Pulls: 5, MeanReward: 3}
+
+	s := NewScheduler(0, []*Arm{a, b, c})
+	picked := s.Pick()
+	if picked != a {
+		t.Fatalf("expected untried arm A to be force-explored, got %s", picked.Name)
+	}
+}
+
+func TestPickExploitsHighestMeanOnceAllArmsTried(t *testing.T) {
+	a := &Arm{Name: "A", Pulls: 1, MeanReward: 1}
+	b := &Arm{Name: "B", Pulls: 1, MeanReward: 5}
+	c := &Arm{Name: "C", Pulls: 1, MeanReward: 3}
+
+	s := NewScheduler(0, []*Arm{a, b, c})
+	picked := s.Pick()
+	if picked != b {
+		t.Fatalf("expected highest-mean arm B, got %s", picked.Name)
+	}
+}
+
+func TestUpdateTracksMeanAndEMA(t *testing.T) {
+	arm := &Arm{Name: "A"}
+	s := NewScheduler(0.1, []*Arm{arm})
+
+	s.Update(arm, 10, time.Second)
+	if arm.Pulls != 1 || arm.MeanReward != 10 || arm.EMARate != 10 {
+		t.Fatalf("after first pull: pulls=%d mean=%v ema=%v", arm.Pulls, arm.MeanReward, arm.EMARate)
+	}
+
+	s.Update(arm, 0, time.Second)
+	if arm.Pulls != 2 || arm.MeanReward != 5 {
+		t.Fatalf("after second pull: pulls=%d mean=%v", arm.Pulls, arm.MeanReward)
+	}
+	wantEMA := emaAlpha*0 + (1-emaAlpha)*10
+	if arm.EMARate != wantEMA {
+		t.Fatalf("ema=%v, want %v", arm.EMARate, wantEMA)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Budget bounds a fuzzing run by episode count, wall-clock duration, or
+// both, whichever is hit first. A zero field means that bound is unused.
+type Budget struct {
+	Episodes int
+	Duration time.Duration
+}
+
+// ParseBudget parses a --fuzztime value. Accepted forms:
+//
+//	"<N>x"          run exactly N episodes
+//	"<D>s"/"m"/"h"  run for duration D
+//	"<D>s+<N>x"     run until whichever bound is hit first
+//
+// An empty spec falls back to defaultEpisodes, matching the previous
+// --episodes-only behavior.
+func ParseBudget(spec string, defaultEpisodes int) (Budget, error) {
+	if spec == "" {
+		return Budget{Episodes: defaultEpisodes}, nil
+	}
+
+	parts := strings.SplitN(spec, "+", 2)
+	var budget Budget
+	for _, part := range parts {
+		if strings.HasSuffix(part, "x") {
+			n, err := strconv.Atoi(strings.TrimSuffix(part, "x"))
+			if err != nil {
+				return Budget{}, fmt.Errorf("fuzztime: invalid episode count %q: %w", part, err)
+			}
+			budget.Episodes = n
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil {
+			return Budget{}, fmt.Errorf("fuzztime: invalid duration %q: %w", part, err)
+		}
+		budget.Duration = d
+	}
+	return budget, nil
+}
+
+// BudgetTracker tracks progress of a running fuzzer against a Budget and
+// reports when the episode loop should stop.
+type BudgetTracker struct {
+	budget   Budget
+	start    time.Time
+	episodes int
+}
+
+// NewBudgetTracker starts tracking budget from now.
+func NewBudgetTracker(budget Budget) *BudgetTracker {
+	return &BudgetTracker{budget: budget, start: time.Now()}
+}
+
+// Tick records that one more episode has completed.
+func (t *BudgetTracker) Tick() {
+	t.episodes++
+}
+
+// Done reports whether the loop should stop because either bound of the
+// budget has been reached. A budget with both bounds zero (e.g. --fuzztime
+// 0x) asks for no work at all, so it's done immediately rather than looping
+// forever.
+func (t *BudgetTracker) Done() bool {
+	if t.budget.Episodes == 0 && t.budget.Duration == 0 {
+		return true
+	}
+	if t.budget.Episodes > 0 && t.episodes >= t.budget.Episodes {
+		return true
+	}
+	if t.budget.Duration > 0 && time.Since(t.start) >= t.budget.Duration {
+		return true
+	}
+	return false
+}
+
+// Episodes returns the number of episodes completed so far.
+func (t *BudgetTracker) Episodes() int {
+	return t.episodes
+}
+
+// Elapsed returns the wall-clock time since the tracker started.
+func (t *BudgetTracker) Elapsed() time.Duration {
+	return time.Since(t.start)
+}
+
+// Summary formats the closing report line printed once a run finishes or is
+// interrupted: total episodes, elapsed time, executions/sec, and the number
+// of unique coverage signals discovered.
+func (t *BudgetTracker) Summary(uniqueSignals int) string {
+	elapsed := t.Elapsed()
+	execPerSec := float64(0)
+	if elapsed.Seconds() > 0 {
+		execPerSec = float64(t.episodes) / elapsed.Seconds()
+	}
+	return fmt.Sprintf(
+		"episodes=%d elapsed=%s exec/sec=%.2f unique-signals=%d",
+		t.episodes, elapsed.Round(time.Millisecond), execPerSec, uniqueSignals,
+	)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBudget(t *testing.T) {
+	cases := []struct {
+		spec string
+		want Budget
+	}{
+		{"", Budget{Episodes: 42}},
+		{"100x", Budget{Episodes: 100}},
+		{"0x", Budget{Episodes: 0}},
+		{"30s", Budget{Duration: 30 * time.Second}},
+		{"2m", Budget{Duration: 2 * time.Minute}},
+		{"30s+100x", Budget{Duration: 30 * time.Second, Episodes: 100}},
+	}
+	for _, c := range cases {
+		got, err := ParseBudget(c.spec, 42)
+		if err != nil {
+			t.Errorf("ParseBudget(%q): unexpected error: %v", c.spec, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("ParseBudget(%q) = %+v, want %+v", c.spec, got, c.want)
+		}
+	}
+}
+
+func TestParseBudgetInvalid(t *testing.T) {
+	for _, spec := range []string{"nope", "x", "100", "30s+", "+100x"} {
+		if _, err := ParseBudget(spec, 42); err == nil {
+			t.Errorf("ParseBudget(%q): expected error, got nil", spec)
+		}
+	}
+}
+
+func TestParseMinimizeBudget(t *testing.T) {
+	got, err := ParseMinimizeBudget("")
+	if err != nil || got != DefaultMinimizeBudget {
+		t.Errorf("ParseMinimizeBudget(\"\") = %+v, %v; want %+v, nil", got, err, DefaultMinimizeBudget)
+	}
+
+	got, err = ParseMinimizeBudget("500x")
+	if err != nil || got != (MinimizeBudget{MaxReplays: 500}) {
+		t.Errorf("ParseMinimizeBudget(\"500x\") = %+v, %v", got, err)
+	}
+
+	got, err = ParseMinimizeBudget("45s")
+	if err != nil || got != (MinimizeBudget{Duration: 45 * time.Second}) {
+		t.Errorf("ParseMinimizeBudget(\"45s\") = %+v, %v", got, err)
+	}
+
+	if _, err := ParseMinimizeBudget("not-a-budget"); err == nil {
+		t.Error("ParseMinimizeBudget(\"not-a-budget\"): expected error, got nil")
+	}
+}
+
+func TestBudgetTrackerEpisodeBound(t *testing.T) {
+	tracker := NewBudgetTracker(Budget{Episodes: 3})
+	for i := 0; i < 3; i++ {
+		if tracker.Done() {
+			t.Fatalf("tracker reported done after only %d episodes", i)
+		}
+		tracker.Tick()
+	}
+	if !tracker.Done() {
+		t.Fatal("tracker should be done after reaching the episode bound")
+	}
+}
+
+func TestBudgetTrackerZeroBudgetIsImmediatelyDone(t *testing.T) {
+	tracker := NewBudgetTracker(Budget{})
+	if !tracker.Done() {
+		t.Fatal("tracker with an all-zero budget should report done immediately")
+	}
+}
+
+func TestBudgetTrackerSummary(t *testing.T) {
+	tracker := NewBudgetTracker(Budget{Episodes: 1})
+	tracker.Tick()
+	summary := tracker.Summary(5)
+	if summary == "" {
+		t.Fatal("expected a non-empty summary line")
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/weng-chenghui/etcd-fuzzing/bandit"
+)
+
+// banditMutator adapts a bandit.Scheduler into a Mutator: every Mutate call
+// lets the scheduler pick the arm for this episode and delegates to that
+// arm's own Mutator, the same selection runEpisode performs for
+// `fuzz --bandit` in run_loop.go. The picked arm is remembered so the
+// paired banditGuider can report its gain back to the scheduler once the
+// episode's new signals are known.
+type banditMutator struct {
+	mu        sync.Mutex
+	scheduler *bandit.Scheduler
+	current   *bandit.Arm
+}
+
+func (m *banditMutator) Mutate(trace *Trace) *Trace {
+	arm := m.scheduler.Pick()
+	m.mu.Lock()
+	m.current = arm
+	m.mu.Unlock()
+	return arm.Mutator.(Mutator).Mutate(trace)
+}
+
+// banditGuider adapts the same scheduler into a Guider: Record attributes
+// the episode's new signals to whichever arm mutator picked for it and
+// forwards them to that arm's own Guider, while Flush drains every
+// registered arm's Guider so none of their on-disk coverage state is lost.
+type banditGuider struct {
+	mu        sync.Mutex
+	scheduler *bandit.Scheduler
+	mutator   *banditMutator
+	lastPull  time.Time
+}
+
+func (g *banditGuider) Record(signals []string) {
+	g.mu.Lock()
+	arm := g.mutator.current
+	elapsed := time.Since(g.lastPull)
+	g.lastPull = time.Now()
+	g.mu.Unlock()
+
+	if arm == nil {
+		return
+	}
+	g.scheduler.Update(arm, len(signals), elapsed)
+	if guider, ok := arm.Guider.(Guider); ok {
+		guider.Record(signals)
+	}
+}
+
+func (g *banditGuider) Flush(path string) error {
+	for _, arm := range g.scheduler.Arms() {
+		guider, ok := arm.Guider.(Guider)
+		if !ok {
+			continue
+		}
+		if err := guider.Flush(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddBandit registers a "bandit" entry driven by scheduler: unlike the fixed
+// combinations registered via Add, it re-picks its Mutator (and attributes
+// the resulting gain) from scheduler's arms on every episode, so compare's
+// output includes a row for the adaptive scheduler running alongside the
+// fixed strategy/mutator/guider baselines.
+func (c *Comparision) AddBandit(name string, scheduler *bandit.Scheduler) {
+	mutator := &banditMutator{scheduler: scheduler}
+	guider := &banditGuider{scheduler: scheduler, mutator: mutator, lastPull: time.Now()}
+	c.Add(name, mutator, guider)
+}
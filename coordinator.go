@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/weng-chenghui/etcd-fuzzing/corpus"
+)
+
+// WorkItem is one unit of mutation work handed to a Worker: a seed trace and
+// the mutator to apply to it before replaying through the worker's own
+// RaftEnvironment. Mutator is commonly the same instance shared across every
+// in-flight WorkItem, and the repo's mutators (NewSwapNodeMutator and
+// friends, CombineMutators) hold RNG/mutation state that isn't safe for
+// concurrent use, so Coordinator serializes calls to it across Workers
+// rather than assuming Mutator is concurrency-safe.
+type WorkItem struct {
+	Seed    *Trace
+	Mutator Mutator
+}
+
+// WorkResult is what a Worker reports back after executing a WorkItem.
+type WorkResult struct {
+	WorkerID int
+	Trace    *Trace
+	Violated bool
+	NewHits  []string
+}
+
+// Coordinator owns the shared Guider state, corpus and mutation queue for a
+// parallel fuzzing run. Workers pull WorkItems off it and report back
+// WorkResults, which the coordinator merges under a lock.
+type Coordinator struct {
+	Guider Guider
+	Corpus *corpus.Corpus
+
+	mu            sync.Mutex
+	episodes      int
+	started       time.Time
+	lastHash      string
+	uniqueSignals map[string]bool
+
+	mutateMu sync.Mutex
+	work     chan WorkItem
+	results  chan WorkResult
+	wg       sync.WaitGroup
+}
+
+// NewCoordinator builds a Coordinator around guider and an optional corpus,
+// with a work queue of the given size.
+func NewCoordinator(guider Guider, c *corpus.Corpus, queueSize int) *Coordinator {
+	return &Coordinator{
+		Guider:        guider,
+		Corpus:        c,
+		started:       time.Now(),
+		uniqueSignals: map[string]bool{},
+		work:          make(chan WorkItem, queueSize),
+		results:       make(chan WorkResult, queueSize),
+	}
+}
+
+// Start launches numWorkers Workers, each with its own RaftEnvironment bound
+// to basePort+i, pulling from the shared work queue.
+func (co *Coordinator) Start(numWorkers int, basePort int, envConfig RaftEnvironmentConfig) {
+	for i := 0; i < numWorkers; i++ {
+		worker := NewWorker(i, basePort, envConfig, &co.mutateMu)
+		co.wg.Add(1)
+		go func(w *Worker) {
+			defer co.wg.Done()
+			w.Run(co.work, co.results)
+		}(worker)
+	}
+}
+
+// Enqueue submits a WorkItem to be picked up by any free worker.
+func (co *Coordinator) Enqueue(item WorkItem) {
+	co.work <- item
+}
+
+// Close signals workers that no more work is coming; it must only be called
+// once every WorkItem has been enqueued.
+func (co *Coordinator) Close() {
+	close(co.work)
+}
+
+// Wait blocks until every worker has drained the work queue and exited,
+// then closes the results channel so a Merge loop draining it terminates.
+func (co *Coordinator) Wait() {
+	co.wg.Wait()
+	close(co.results)
+}
+
+// Merge drains results, folding each into the shared Guider's coverage and,
+// if the trace discovered new signals, adding it to the corpus with
+// ParentHash set to the corpus entry Merge added just before it; the seed
+// the trace was mutated from is not recorded. Every
+// result that either violated the Checker or landed new coverage is passed
+// to onInteresting, mirroring run_loop.go's violated-or-new-coverage
+// minimizer trigger; pass nil to ignore them. Merge returns once the results
+// channel is closed by Wait.
+func (co *Coordinator) Merge(onInteresting func(WorkResult)) {
+	for result := range co.results {
+		if co.Guider != nil {
+			co.Guider.Record(result.NewHits)
+		}
+		co.mu.Lock()
+		co.episodes++
+		for _, s := range result.NewHits {
+			co.uniqueSignals[s] = true
+		}
+		if len(result.NewHits) > 0 && co.Corpus != nil {
+			if data, err := json.Marshal(result.Trace); err == nil {
+				entry, err := co.Corpus.Add(data, corpus.Meta{
+					Signals:    result.NewHits,
+					Episode:    co.episodes,
+					ParentHash: co.lastHash,
+				})
+				if err == nil {
+					co.lastHash = entry.Hash
+				}
+			}
+		}
+		co.mu.Unlock()
+		if onInteresting != nil && (result.Violated || len(result.NewHits) > 0) {
+			onInteresting(result)
+		}
+	}
+}
+
+// Episodes returns the number of results merged so far.
+func (co *Coordinator) Episodes() int {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	return co.episodes
+}
+
+// UniqueSignals returns the number of distinct guider signals discovered
+// across every merged result so far.
+func (co *Coordinator) UniqueSignals() int {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	return len(co.uniqueSignals)
+}
+
+// ExecsPerSecond reports the aggregate executions/sec across all workers
+// since the coordinator started, for periodic progress reporting.
+func (co *Coordinator) ExecsPerSecond() float64 {
+	elapsed := time.Since(co.started).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(co.Episodes()) / elapsed
+}
+
+// Summary formats a one-line progress report of the aggregate throughput.
+func (co *Coordinator) Summary() string {
+	return fmt.Sprintf("episodes=%d exec/sec=%.2f", co.Episodes(), co.ExecsPerSecond())
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/weng-chenghui/etcd-fuzzing/corpus"
+)
+
+// seedRefreshInterval bounds how often nextSeed re-reads seedCorpus: taking
+// its lock and copying every entry on every single enqueue would make the
+// corpus a bottleneck once it's large, so a batch is reused for a while and
+// re-fetched periodically to pick up traces the Merge goroutine just added.
+const seedRefreshInterval = 64
+
+// nextSeed picks the trace a parallel worker should mutate next: a
+// round-robin pick over a periodically refreshed batch of seedCorpus's
+// entries when it has any, so workers explore from previously discovered
+// coverage instead of always starting cold, or a blank trace shaped by
+// config when the corpus is empty.
+func nextSeed(config FuzzerConfig, batch []*corpus.Entry, episode int) *Trace {
+	if len(batch) > 0 {
+		var trace Trace
+		if err := json.Unmarshal(batch[episode%len(batch)].Data, &trace); err == nil {
+			return &trace
+		}
+	}
+	return &Trace{
+		MaxMessages:    config.MaxMessages,
+		CrashQuota:     config.CrashQuota,
+		NumberRequests: config.NumberRequests,
+	}
+}
+
+// RunParallelFuzzLoop is the multi-worker counterpart to RunFuzzLoop: it
+// starts a Coordinator with config.Workers Workers, each bound to its own
+// RaftEnvironment on basePort+i, and keeps the work queue fed with mutated
+// seeds until config.Budget is exhausted or interrupt fires. Before any of
+// that, seedCorpus is replayed once into config.Guider (see reseedCorpus) so
+// a resumed run doesn't start with cold coverage. Every result is merged
+// into config.Guider and seedCorpus exactly like the single-threaded loop,
+// and any result that violated the Checker or landed new coverage is handed
+// to minimizer so a small, readable repro is saved under savePath/minimized.
+// On the way out it flushes the guider's coverage/trace state to savePath
+// and prints the closing summary line.
+//
+// RunParallelFuzzLoop does not consult a bandit scheduler: arm selection is
+// inherently sequential, so FuzzCommand rejects --bandit together with
+// --workers > 1 before this is ever called.
+func RunParallelFuzzLoop(config FuzzerConfig, minimizer *Minimizer, seedCorpus *corpus.Corpus, savePath string, basePort int, interrupt <-chan os.Signal) *BudgetTracker {
+	reseedCorpus(config, seedCorpus)
+
+	tracker := NewBudgetTracker(config.Budget)
+
+	co := NewCoordinator(config.Guider, seedCorpus, config.Workers*4)
+	co.Start(config.Workers, basePort, config.RaftEnvironmentConfig)
+
+	merged := make(chan struct{})
+	go func() {
+		co.Merge(func(result WorkResult) {
+			if minimizer != nil {
+				minimized := minimizer.Minimize(result.Trace, reproduces(config, result.Violated, result.NewHits))
+				if _, err := SaveMinimizedTrace(savePath, minimized); err != nil {
+					fmt.Fprintf(os.Stderr, "coordinator: failed to save minimized trace: %v\n", err)
+				}
+			}
+		})
+		close(merged)
+	}()
+
+	var seedBatch []*corpus.Entry
+enqueueLoop:
+	for episode := 0; !tracker.Done(); episode++ {
+		select {
+		case <-interrupt:
+			break enqueueLoop
+		default:
+		}
+		if seedCorpus != nil && episode%seedRefreshInterval == 0 {
+			seedBatch = seedCorpus.Entries()
+		}
+		co.Enqueue(WorkItem{Seed: nextSeed(config, seedBatch, episode), Mutator: config.Mutator})
+		tracker.Tick()
+	}
+
+	co.Close()
+	co.Wait()
+	<-merged
+
+	if config.Guider != nil {
+		config.Guider.Flush(savePath)
+	}
+	fmt.Println(tracker.Summary(co.UniqueSignals()))
+	return tracker
+}
@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+type fakeGuider struct {
+	recorded [][]string
+}
+
+func (g *fakeGuider) Record(signals []string) { g.recorded = append(g.recorded, signals) }
+func (g *fakeGuider) Flush(path string) error  { return nil }
+
+func TestCoordinatorMergeAggregatesAndLocksResults(t *testing.T) {
+	guider := &fakeGuider{}
+	co := NewCoordinator(guider, nil, 4)
+
+	go func() {
+		co.results <- WorkResult{WorkerID: 0, Trace: &Trace{}, NewHits: []string{"a"}}
+		co.results <- WorkResult{WorkerID: 1, Trace: &Trace{}, NewHits: []string{"b", "c"}}
+		co.results <- WorkResult{WorkerID: 0, Trace: &Trace{}, Violated: true}
+		close(co.results)
+	}()
+
+	var interesting int
+	co.Merge(func(result WorkResult) {
+		interesting++
+	})
+
+	if co.Episodes() != 3 {
+		t.Errorf("Episodes() = %d, want 3", co.Episodes())
+	}
+	if co.UniqueSignals() != 3 {
+		t.Errorf("UniqueSignals() = %d, want 3", co.UniqueSignals())
+	}
+	// Two results land new coverage, one is a bare violation: all three must
+	// reach onInteresting, matching run_loop.go's violated-or-new-coverage
+	// minimizer trigger.
+	if interesting != 3 {
+		t.Errorf("onInteresting fired %d times, want 3", interesting)
+	}
+	if len(guider.recorded) != 3 {
+		t.Errorf("guider.Record called %d times, want 3", len(guider.recorded))
+	}
+}
@@ -0,0 +1,223 @@
+// Package corpus stores "interesting" fuzzing traces on disk so they carry
+// over between invocations instead of being regenerated in memory every run.
+package corpus
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Meta is the sidecar recorded alongside each trace: which guider signals it
+// first hit, which episode discovered it, and its parent's hash for
+// lineage.
+type Meta struct {
+	Signals    []string `json:"signals"`
+	Episode    int      `json:"episode"`
+	ParentHash string   `json:"parentHash,omitempty"`
+}
+
+// Entry is one corpus member: its content hash, metadata, and the raw
+// serialized trace bytes (caller-defined format; the corpus package never
+// interprets them).
+type Entry struct {
+	Hash string
+	Meta Meta
+	Data []byte
+}
+
+// Corpus is an on-disk, size-bounded cache of interesting traces with LRU
+// eviction that never evicts an entry that is the sole discoverer of some
+// guider signal. It's safe for concurrent use: every exported method takes
+// an internal lock, so a parallel Coordinator and a single-threaded run
+// loop can share one Corpus without their own synchronization.
+type Corpus struct {
+	mu         sync.Mutex
+	dir        string
+	maxEntries int
+
+	entries map[string]*Entry
+	lru     *list.List
+	elems   map[string]*list.Element
+
+	// signalOwners maps a guider signal to the set of entry hashes whose
+	// Meta.Signals first hit it, so a sole discoverer can be identified.
+	signalOwners map[string]map[string]bool
+}
+
+// Open loads an existing on-disk corpus from dir (creating dir if it
+// doesn't exist yet), bounding it to at most maxEntries entries.
+func Open(dir string, maxEntries int) (*Corpus, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	c := &Corpus{
+		dir:          dir,
+		maxEntries:   maxEntries,
+		entries:      map[string]*Entry{},
+		lru:          list.New(),
+		elems:        map[string]*list.Element{},
+		signalOwners: map[string]map[string]bool{},
+	}
+	entries, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		c.insert(e)
+	}
+	return c, nil
+}
+
+// load reads every <hash>.trace / <hash>.meta pair under dir.
+func (c *Corpus) load() ([]*Entry, error) {
+	matches, err := filepath.Glob(filepath.Join(c.dir, "*.trace"))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*Entry, 0, len(matches))
+	for _, tracePath := range matches {
+		hash := strings.TrimSuffix(filepath.Base(tracePath), ".trace")
+		data, err := os.ReadFile(tracePath)
+		if err != nil {
+			return nil, err
+		}
+		metaData, err := os.ReadFile(filepath.Join(c.dir, hash+".meta"))
+		if err != nil {
+			return nil, err
+		}
+		var meta Meta
+		if err := json.Unmarshal(metaData, &meta); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &Entry{Hash: hash, Meta: meta, Data: data})
+	}
+	return entries, nil
+}
+
+// Entries returns every entry currently held in the corpus, least recently
+// used first.
+func (c *Corpus) Entries() []*Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]*Entry, 0, len(c.entries))
+	for e := c.lru.Front(); e != nil; e = e.Next() {
+		out = append(out, c.entries[e.Value.(string)])
+	}
+	return out
+}
+
+// Add computes data's hash, persists it and meta under dir, merges it into
+// the in-memory corpus, and evicts the least-recently-used entry that isn't
+// the sole discoverer of any signal if the corpus is now over capacity.
+func (c *Corpus) Add(data []byte, meta Meta) (*Entry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hash := hashBytes(data)
+	if _, exists := c.entries[hash]; exists {
+		c.touch(hash)
+		return c.entries[hash], nil
+	}
+
+	entry := &Entry{Hash: hash, Meta: meta, Data: data}
+	if err := os.WriteFile(filepath.Join(c.dir, hash+".trace"), data, 0644); err != nil {
+		return nil, err
+	}
+	metaData, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, hash+".meta"), metaData, 0644); err != nil {
+		return nil, err
+	}
+
+	c.insert(entry)
+	c.evictIfNeeded()
+	return entry, nil
+}
+
+func (c *Corpus) insert(e *Entry) {
+	c.entries[e.Hash] = e
+	c.elems[e.Hash] = c.lru.PushBack(e.Hash)
+	for _, signal := range e.Meta.Signals {
+		if c.signalOwners[signal] == nil {
+			c.signalOwners[signal] = map[string]bool{}
+		}
+		c.signalOwners[signal][e.Hash] = true
+	}
+}
+
+// touch marks hash as most recently used.
+func (c *Corpus) touch(hash string) {
+	if elem, ok := c.elems[hash]; ok {
+		c.lru.MoveToBack(elem)
+	}
+}
+
+// isSoleDiscoverer reports whether hash is the only entry credited with
+// discovering at least one of its signals.
+func (c *Corpus) isSoleDiscoverer(hash string) bool {
+	entry, ok := c.entries[hash]
+	if !ok {
+		return false
+	}
+	for _, signal := range entry.Meta.Signals {
+		if owners := c.signalOwners[signal]; len(owners) == 1 && owners[hash] {
+			return true
+		}
+	}
+	return false
+}
+
+// evictIfNeeded removes least-recently-used entries, skipping sole
+// discoverers, until the corpus is back within maxEntries. If every entry
+// left is a sole discoverer, the corpus stays above maxEntries.
+func (c *Corpus) evictIfNeeded() {
+	if c.maxEntries <= 0 {
+		return
+	}
+	for elem := c.lru.Front(); len(c.entries) > c.maxEntries && elem != nil; {
+		hash := elem.Value.(string)
+		next := elem.Next()
+		if !c.isSoleDiscoverer(hash) {
+			c.evict(hash, elem)
+		}
+		elem = next
+	}
+}
+
+func (c *Corpus) evict(hash string, elem *list.Element) {
+	entry := c.entries[hash]
+	for _, signal := range entry.Meta.Signals {
+		delete(c.signalOwners[signal], hash)
+	}
+	delete(c.entries, hash)
+	delete(c.elems, hash)
+	c.lru.Remove(elem)
+	os.Remove(filepath.Join(c.dir, hash+".trace"))
+	os.Remove(filepath.Join(c.dir, hash+".meta"))
+}
+
+// Len returns the number of entries currently held in the corpus.
+func (c *Corpus) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// String implements fmt.Stringer, mostly for log lines.
+func (e *Entry) String() string {
+	return fmt.Sprintf("%s (episode=%d signals=%d)", e.Hash, e.Meta.Episode, len(e.Meta.Signals))
+}
@@ -0,0 +1,97 @@
+package corpus
+
+import "testing"
+
+func TestAddPersistsAndReloads(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, 10)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	entry, err := c.Add([]byte("trace-a"), Meta{Signals: []string{"line:1"}, Episode: 1})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if entry.Meta.ParentHash != "" {
+		t.Fatalf("expected no parent hash, got %q", entry.Meta.ParentHash)
+	}
+
+	reopened, err := Open(dir, 10)
+	if err != nil {
+		t.Fatalf("re-Open: %v", err)
+	}
+	if reopened.Len() != 1 {
+		t.Fatalf("reopened corpus has %d entries, want 1", reopened.Len())
+	}
+}
+
+func TestAddSetsParentHash(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, 10)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	parent, err := c.Add([]byte("trace-a"), Meta{Signals: []string{"line:1"}, Episode: 1})
+	if err != nil {
+		t.Fatalf("Add parent: %v", err)
+	}
+	child, err := c.Add([]byte("trace-b"), Meta{Signals: []string{"line:2"}, Episode: 2, ParentHash: parent.Hash})
+	if err != nil {
+		t.Fatalf("Add child: %v", err)
+	}
+	if child.Meta.ParentHash != parent.Hash {
+		t.Errorf("child.Meta.ParentHash = %q, want %q", child.Meta.ParentHash, parent.Hash)
+	}
+}
+
+func TestEvictionSkipsSoleDiscoverer(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, 2)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	// a is the sole discoverer of "rare"; it's touched first so it's the
+	// least-recently-used entry once b and d are added, which would make a
+	// a normal LRU's first eviction victim.
+	a, err := c.Add([]byte("a"), Meta{Signals: []string{"rare"}, Episode: 1})
+	if err != nil {
+		t.Fatalf("Add a: %v", err)
+	}
+	if _, err := c.Add([]byte("b"), Meta{Signals: []string{"common"}, Episode: 2}); err != nil {
+		t.Fatalf("Add b: %v", err)
+	}
+	if _, err := c.Add([]byte("d"), Meta{Signals: []string{"common"}, Episode: 3}); err != nil {
+		t.Fatalf("Add d: %v", err)
+	}
+
+	if c.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", c.Len())
+	}
+	found := false
+	for _, e := range c.Entries() {
+		if e.Hash == a.Hash {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("sole discoverer of a signal was evicted despite being least-recently-used")
+	}
+}
+
+func TestAddIsIdempotentOnDuplicateData(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, 10)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := c.Add([]byte("same"), Meta{Signals: []string{"x"}, Episode: 1}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if _, err := c.Add([]byte("same"), Meta{Signals: []string{"x"}, Episode: 2}); err != nil {
+		t.Fatalf("Add dup: %v", err)
+	}
+	if c.Len() != 1 {
+		t.Errorf("Len() = %d, want 1 after adding identical data twice", c.Len())
+	}
+}
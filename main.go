@@ -2,8 +2,14 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
 
 	"github.com/spf13/cobra"
+	"github.com/weng-chenghui/etcd-fuzzing/bandit"
+	"github.com/weng-chenghui/etcd-fuzzing/corpus"
 )
 
 var (
@@ -17,6 +23,20 @@ var (
 	host         string
 	port         int
 	address      string
+
+	banditEnabled  bool
+	banditEpsilon  float64
+	banditArmSpecs []string
+
+	minimizeBudget string
+	tracePath      string
+
+	fuzztime string
+
+	corpusDir        string
+	corpusMaxEntries int
+
+	workers int
 )
 
 func main() {
@@ -29,9 +49,12 @@ func main() {
 	rootCommand.PersistentFlags().IntVar(&numRuns, "runs", 5, "Number of runs to average over")
 	rootCommand.PersistentFlags().BoolVar(&recordTraces, "record-traces", false, "Record the traces explored")
 	rootCommand.PersistentFlags().StringVar(&host, "host", "127.0.0.1", "Host address to use")
-	rootCommand.PersistentFlags().IntVar(&port, "port", 2023, "Port to use")
+	rootCommand.PersistentFlags().IntVar(&port, "port", 2023, "Base port to use; worker i binds to port+i")
+	rootCommand.PersistentFlags().StringVar(&fuzztime, "fuzztime", "", "Fuzzing budget: <N>x episodes, <D>s/m/h wall-clock, or <D>s+<N>x for whichever hits first (default: --episodes count)")
+	rootCommand.PersistentFlags().IntVar(&workers, "workers", runtime.NumCPU(), "Number of parallel workers, each with its own RaftEnvironment on base-port+i")
 	rootCommand.AddCommand(FuzzCommand())
 	rootCommand.AddCommand(OneCommand())
+	rootCommand.AddCommand(ReplayCommand())
 
 	if err := rootCommand.Execute(); err != nil {
 		fmt.Println(err)
@@ -39,13 +62,23 @@ func main() {
 }
 
 func FuzzCommand() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use: "fuzz",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if banditEnabled && workers > 1 {
+				return fmt.Errorf("fuzz: --bandit is not supported with --workers > 1 (arm selection is sequential); pass --workers 1")
+			}
+
 			address = fmt.Sprintf("%s:%d", host, port)
-			fuzzer := NewFuzzer(&FuzzerConfig{
-				Iterations: episodes,
+			fuzzBudget, err := ParseBudget(fuzztime, episodes)
+			if err != nil {
+				return err
+			}
+
+			config := &FuzzerConfig{
+				Budget:     fuzzBudget,
 				Steps:      horizon,
+				Workers:    workers,
 				Strategy:   NewRandomStrategy(),
 				Guider:     NewLineCoverageGuider(address, "traces", recordTraces),
 				Mutator:    &EmptyMutator{},
@@ -62,24 +95,203 @@ func FuzzCommand() *cobra.Command {
 				SeedPopulationSize: 10,
 				// Must specify a value otherwise it throws the div zero error.
 				ReseedFrequency: 2000,
-			})
-			fuzzer.Run()
+			}
+
+			minBudget, err := ParseMinimizeBudget(minimizeBudget)
+			if err != nil {
+				return err
+			}
+			config.MinimizeBudget = minBudget
+			minimizer := NewMinimizer(minBudget)
+
+			dir := corpusDir
+			if dir == "" {
+				dir = filepath.Join(savePath, "corpus")
+			}
+			seedCorpus, err := corpus.Open(dir, corpusMaxEntries)
+			if err != nil {
+				return err
+			}
+			config.Corpus = seedCorpus
+
+			var scheduler *bandit.Scheduler
+			if banditEnabled {
+				arms, err := buildBanditArms(banditArmSpecs)
+				if err != nil {
+					return err
+				}
+				scheduler = bandit.NewScheduler(banditEpsilon, arms)
+				config.Bandit = scheduler
+			}
+
+			interrupt := make(chan os.Signal, 1)
+			signal.Notify(interrupt, os.Interrupt)
+			config.Interrupt = interrupt
+
+			// Every episode consults the bandit scheduler for its arm (when
+			// enabled) and feeds any Checker violation or newly discovered
+			// coverage to the minimizer, instead of fixing one combination
+			// for the whole run and never shrinking interesting traces.
+			// The loop itself is bounded by config.Budget (episodes,
+			// duration, or both) and stops gracefully on SIGINT.
+			//
+			// --workers > 1 fans episodes out across a Coordinator/Worker
+			// pool instead, each worker on its own RaftEnvironment; the
+			// bandit scheduler only applies to the single-threaded loop
+			// since arm selection is inherently sequential.
+			if workers > 1 {
+				RunParallelFuzzLoop(*config, minimizer, seedCorpus, savePath, port, interrupt)
+			} else {
+				RunFuzzLoop(*config, scheduler, minimizer, seedCorpus, savePath, interrupt)
+			}
+			signal.Stop(interrupt)
+
+			if scheduler != nil {
+				if err := scheduler.Save(filepath.Join(savePath, "bandit.json")); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&banditEnabled, "bandit", false, "Use an epsilon-greedy bandit to pick the strategy/mutator/guider each episode")
+	cmd.Flags().Float64Var(&banditEpsilon, "bandit-epsilon", 0.1, "Exploration rate for the bandit scheduler")
+	cmd.Flags().StringArrayVar(&banditArmSpecs, "bandit-arm", nil, "Register a bandit arm as name=strategy:mutator:guider (repeatable)")
+	cmd.Flags().StringVar(&minimizeBudget, "minimize-budget", "", "Budget for minimizing an interesting trace: a duration like 60s, or a replay count like 1000x (default: 60s / 1000 replays)")
+	cmd.Flags().StringVar(&corpusDir, "corpus-dir", "", "Directory to persist the interesting-trace corpus (default: <save>/corpus)")
+	cmd.Flags().IntVar(&corpusMaxEntries, "corpus-max-entries", 1000, "Maximum number of traces to keep in the on-disk corpus, LRU-evicted (sole discoverers of a signal are never evicted)")
+	return cmd
+}
+
+// ReplayCommand deterministically re-runs a trace previously saved by the
+// fuzzer or the minimizer against a fresh RaftEnvironment, for debugging.
+func ReplayCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Replay a saved trace against RaftEnvironment",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if tracePath == "" {
+				return fmt.Errorf("replay: --trace is required")
+			}
+			trace, err := LoadTrace(tracePath)
+			if err != nil {
+				return err
+			}
+			envConfig := trace.Env
+			if envConfig.Replicas == 0 {
+				// Traces saved before the env config was persisted (or
+				// otherwise missing one) fall back to the fuzz profile, the
+				// previous hardcoded behavior, but can't be guaranteed to
+				// replay deterministically against whatever produced them.
+				fmt.Fprintln(os.Stderr, "replay: trace has no saved environment config, falling back to fuzz defaults")
+				envConfig = RaftEnvironmentConfig{
+					Replicas:      replicas,
+					ElectionTick:  20,
+					HeartbeatTick: 2,
+					TicksPerStep:  2,
+				}
+			}
+			env := NewRaftEnvironment(envConfig)
+			violated, _, err := env.Replay(trace)
+			if err != nil {
+				return err
+			}
+			if violated {
+				fmt.Println("replay: violation reproduced")
+			} else {
+				fmt.Println("replay: no violation observed")
+			}
 			return nil
 		},
 	}
+	cmd.Flags().StringVar(&tracePath, "trace", "", "Path to a saved trace JSON file")
+	return cmd
+}
+
+// buildBanditArms resolves the repeated --bandit-arm specs into bandit.Arms,
+// constructing the underlying Strategy/Mutator/Guider for each from the same
+// building blocks the compare subcommand uses.
+func buildBanditArms(specs []string) ([]*bandit.Arm, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("--bandit requires at least one --bandit-arm name=strategy:mutator:guider")
+	}
+	arms := make([]*bandit.Arm, 0, len(specs))
+	for _, spec := range specs {
+		name, strategyName, mutatorName, guiderName, err := bandit.ParseArmSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		strategy, err := buildStrategy(strategyName)
+		if err != nil {
+			return nil, err
+		}
+		mutator, err := buildMutator(mutatorName)
+		if err != nil {
+			return nil, err
+		}
+		guider, err := buildGuider(guiderName)
+		if err != nil {
+			return nil, err
+		}
+		arms = append(arms, &bandit.Arm{Name: name, Strategy: strategy, Mutator: mutator, Guider: guider})
+	}
+	return arms, nil
+}
+
+func buildStrategy(name string) (Strategy, error) {
+	switch name {
+	case "random":
+		return NewRandomStrategy(), nil
+	default:
+		return nil, fmt.Errorf("bandit: unknown strategy %q", name)
+	}
+}
+
+func buildMutator(name string) (Mutator, error) {
+	switch name {
+	case "empty":
+		return &EmptyMutator{}, nil
+	case "combined":
+		return CombineMutators(NewSwapCrashNodeMutator(2), NewSwapNodeMutator(20), NewSwapMaxMessagesMutator(20)), nil
+	default:
+		return nil, fmt.Errorf("bandit: unknown mutator %q", name)
+	}
+}
+
+func buildGuider(name string) (Guider, error) {
+	switch name {
+	case "line":
+		return NewLineCoverageGuider(address, "traces", recordTraces), nil
+	case "trace":
+		return NewTraceCoverageGuider(address, "traces", recordTraces), nil
+	case "tlcstate":
+		return NewTLCStateGuider(address, "traces", recordTraces), nil
+	default:
+		return nil, fmt.Errorf("bandit: unknown guider %q", name)
+	}
 }
 
 func OneCommand() *cobra.Command {
 	return &cobra.Command{
 		Use: "compare",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			address = fmt.Sprintf("%s:%d", host, port)
+			fuzzBudget, err := ParseBudget(fuzztime, episodes)
+			if err != nil {
+				return err
+			}
+			interrupt := make(chan os.Signal, 1)
+			signal.Notify(interrupt, os.Interrupt)
+			defer signal.Stop(interrupt)
+
 			c := NewComparision(savePath, &FuzzerConfig{
-				Iterations: episodes,
+				Budget:     fuzzBudget,
 				Steps:      horizon,
+				Workers:    workers,
 				Strategy:   NewRandomStrategy(),
 				Mutator:    &EmptyMutator{},
 				Checker:    SerializabilityChecker(),
+				Interrupt:  interrupt,
 				RaftEnvironmentConfig: RaftEnvironmentConfig{
 					Replicas: replicas,
 					// Higher election tick gives random better chances. (less timeouts)
@@ -103,8 +315,14 @@ func OneCommand() *cobra.Command {
 			c.Add("lineCov", combinedMutator, NewLineCoverageGuider(address, "traces", recordTraces))
 			c.Add("tlcstate", combinedMutator, NewTLCStateGuider(address, "traces", recordTraces))
 			c.Add("random", &EmptyMutator{}, NewTLCStateGuider(address, "traces", recordTraces))
+			c.AddBandit("bandit", bandit.NewScheduler(0.1, []*bandit.Arm{
+				{Name: "traceCov", Strategy: NewRandomStrategy(), Mutator: combinedMutator, Guider: NewTraceCoverageGuider(address, "traces", recordTraces)},
+				{Name: "lineCov", Strategy: NewRandomStrategy(), Mutator: combinedMutator, Guider: NewLineCoverageGuider(address, "traces", recordTraces)},
+				{Name: "tlcstate", Strategy: NewRandomStrategy(), Mutator: combinedMutator, Guider: NewTLCStateGuider(address, "traces", recordTraces)},
+			}))
 
 			c.Run()
+			return nil
 		},
 	}
 }
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinimizeBudget bounds how much work Minimizer.Minimize is willing to do,
+// mirroring the duration-or-count shape of Go's fuzzminimizetime.
+type MinimizeBudget struct {
+	Duration   time.Duration
+	MaxReplays int
+}
+
+// DefaultMinimizeBudget is what ParseMinimizeBudget returns for an empty spec.
+var DefaultMinimizeBudget = MinimizeBudget{Duration: 60 * time.Second, MaxReplays: 1000}
+
+// ParseMinimizeBudget parses a flag value of either "<N>x" (at most N replay
+// attempts) or a Go duration string like "60s" (wall-clock budget).
+func ParseMinimizeBudget(spec string) (MinimizeBudget, error) {
+	if spec == "" {
+		return DefaultMinimizeBudget, nil
+	}
+	if strings.HasSuffix(spec, "x") {
+		n, err := strconv.Atoi(strings.TrimSuffix(spec, "x"))
+		if err != nil {
+			return MinimizeBudget{}, fmt.Errorf("minimizer: invalid replay count %q: %w", spec, err)
+		}
+		return MinimizeBudget{MaxReplays: n}, nil
+	}
+	d, err := time.ParseDuration(spec)
+	if err != nil {
+		return MinimizeBudget{}, fmt.Errorf("minimizer: invalid duration %q: %w", spec, err)
+	}
+	return MinimizeBudget{Duration: d}, nil
+}
+
+// ReproduceFunc replays trace against a fresh RaftEnvironment and reports
+// whether the original violation or coverage signal still reproduces.
+type ReproduceFunc func(trace *Trace) bool
+
+// Minimizer greedily shrinks a failing or otherwise interesting Trace while a
+// ReproduceFunc keeps reporting true, so bugs found deep in a 50-step horizon
+// are left with only the events that actually matter.
+type Minimizer struct {
+	Budget MinimizeBudget
+}
+
+// NewMinimizer builds a Minimizer bounded by budget.
+func NewMinimizer(budget MinimizeBudget) *Minimizer {
+	return &Minimizer{Budget: budget}
+}
+
+// Minimize returns a shrunk copy of trace, leaving trace itself unchanged:
+// first a fixed-point pass that deletes one event at a time as long as
+// reproduces still holds, then a binary search over the quantitative fields
+// (MaxMessages, CrashQuota, NumberRequests) shrinking each downward while
+// reproduction still holds.
+func (m *Minimizer) Minimize(trace *Trace, reproduces ReproduceFunc) *Trace {
+	deadline := time.Time{}
+	if m.Budget.Duration > 0 {
+		deadline = time.Now().Add(m.Budget.Duration)
+	}
+	replays := 0
+	budgetExhausted := func() bool {
+		if m.Budget.MaxReplays > 0 && replays >= m.Budget.MaxReplays {
+			return true
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return true
+		}
+		return false
+	}
+
+	current := trace.Clone()
+	for {
+		shrunkAny := false
+		for i := 0; i < len(current.Events); i++ {
+			if budgetExhausted() {
+				return current
+			}
+			candidate := current.Clone()
+			candidate.Events = append(append([]TraceEvent{}, candidate.Events[:i]...), candidate.Events[i+1:]...)
+			replays++
+			if reproduces(candidate) {
+				current = candidate
+				shrunkAny = true
+				i--
+			}
+		}
+		if !shrunkAny {
+			break
+		}
+	}
+
+	current.MaxMessages = m.shrinkField(current.MaxMessages, budgetExhausted, &replays, func(v int) bool {
+		candidate := current.Clone()
+		candidate.MaxMessages = v
+		return reproduces(candidate)
+	})
+	current.CrashQuota = m.shrinkField(current.CrashQuota, budgetExhausted, &replays, func(v int) bool {
+		candidate := current.Clone()
+		candidate.CrashQuota = v
+		return reproduces(candidate)
+	})
+	current.NumberRequests = m.shrinkField(current.NumberRequests, budgetExhausted, &replays, func(v int) bool {
+		candidate := current.Clone()
+		candidate.NumberRequests = v
+		return reproduces(candidate)
+	})
+
+	return current
+}
+
+// shrinkField binary searches [0, value] for the smallest value for which
+// holds still returns true, stopping early once the budget is exhausted.
+// hi, not lo, is returned: hi starts at value (which reproduces, since it's
+// the field already proven to reproduce before minimization started) and is
+// only ever lowered to a mid that holds() confirmed still reproduces, so
+// it's always a value known to work. lo is just an unconfirmed lower bound,
+// and returning it (especially on early budget exhaustion, where lo may
+// still be its initial 0) can hand back a value that never reproduces.
+func (m *Minimizer) shrinkField(value int, budgetExhausted func() bool, replays *int, holds func(int) bool) int {
+	lo, hi := 0, value
+	for lo < hi {
+		if budgetExhausted() {
+			break
+		}
+		mid := (lo + hi) / 2
+		*replays++
+		if holds(mid) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return hi
+}
+
+// SaveMinimizedTrace persists trace under savePath/minimized/<hash>.json.
+func SaveMinimizedTrace(savePath string, trace *Trace) (string, error) {
+	dir := filepath.Join(savePath, "minimized")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, trace.Hash()+".json")
+	return path, trace.Save(path)
+}
+
+// Trace is the ordered record of scheduling choices, message deliveries and
+// crash events fed into a RaftEnvironment during one episode, along with the
+// quantitative knobs (max messages, crash quota, request count) that shaped
+// it and the RaftEnvironment tick configuration it was produced under,
+// so a saved trace can be replayed deterministically later regardless of
+// which command's ElectionTick/TicksPerStep defaults happened to be current
+// at replay time. Port is zeroed before persisting: it's an artifact of
+// whichever worker produced the trace, not part of its reproducibility.
+type Trace struct {
+	Events         []TraceEvent          `json:"events"`
+	MaxMessages    int                   `json:"maxMessages"`
+	CrashQuota     int                   `json:"crashQuota"`
+	NumberRequests int                   `json:"numberRequests"`
+	Env            RaftEnvironmentConfig `json:"env"`
+}
+
+// TraceEvent is a single step of a Trace: a scheduling choice, a message
+// delivery, or a crash/restart of a replica.
+type TraceEvent struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Clone returns a deep copy of t, so callers can mutate a candidate trace
+// without disturbing the one being minimized.
+func (t *Trace) Clone() *Trace {
+	events := make([]TraceEvent, len(t.Events))
+	copy(events, t.Events)
+	return &Trace{
+		Events:         events,
+		MaxMessages:    t.MaxMessages,
+		CrashQuota:     t.CrashQuota,
+		NumberRequests: t.NumberRequests,
+		Env:            t.Env,
+	}
+}
+
+// Hash returns a stable content hash used to name trace files on disk.
+func (t *Trace) Hash() string {
+	data, _ := json.Marshal(t)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Save writes t to path as JSON.
+func (t *Trace) Save(path string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadTrace reads a Trace previously written by Save.
+func LoadTrace(path string) (*Trace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t Trace
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func ev(n int) TraceEvent {
+	data, _ := json.Marshal(n)
+	return TraceEvent{Kind: "deliver", Data: data}
+}
+
+func TestMinimizeDropsIrrelevantEvents(t *testing.T) {
+	trace := &Trace{
+		Events:         []TraceEvent{ev(1), ev(2), ev(3), ev(4), ev(5)},
+		MaxMessages:    10,
+		CrashQuota:     2,
+		NumberRequests: 5,
+	}
+
+	// Only event index 2 (value 3) matters for reproduction.
+	reproduces := func(candidate *Trace) bool {
+		for _, e := range candidate.Events {
+			var n int
+			if err := json.Unmarshal(e.Data, &n); err == nil && n == 3 {
+				return true
+			}
+		}
+		return false
+	}
+
+	m := NewMinimizer(MinimizeBudget{MaxReplays: 1000})
+	minimized := m.Minimize(trace, reproduces)
+
+	if len(minimized.Events) != 1 {
+		t.Fatalf("expected exactly 1 event to survive, got %d", len(minimized.Events))
+	}
+	var n int
+	if err := json.Unmarshal(minimized.Events[0].Data, &n); err != nil || n != 3 {
+		t.Fatalf("expected the surviving event to be 3, got %v (err=%v)", minimized.Events[0], err)
+	}
+}
+
+func TestMinimizeShrinksQuantitativeFields(t *testing.T) {
+	trace := &Trace{MaxMessages: 100, CrashQuota: 20, NumberRequests: 50}
+
+	reproduces := func(candidate *Trace) bool {
+		return candidate.MaxMessages >= 7 && candidate.CrashQuota >= 3 && candidate.NumberRequests >= 1
+	}
+
+	m := NewMinimizer(MinimizeBudget{MaxReplays: 1000})
+	minimized := m.Minimize(trace, reproduces)
+
+	if minimized.MaxMessages != 7 {
+		t.Errorf("MaxMessages = %d, want 7", minimized.MaxMessages)
+	}
+	if minimized.CrashQuota != 3 {
+		t.Errorf("CrashQuota = %d, want 3", minimized.CrashQuota)
+	}
+	if minimized.NumberRequests != 1 {
+		t.Errorf("NumberRequests = %d, want 1", minimized.NumberRequests)
+	}
+}
+
+func TestMinimizeRespectsReplayBudget(t *testing.T) {
+	trace := &Trace{Events: []TraceEvent{ev(1), ev(2), ev(3), ev(4)}}
+
+	calls := 0
+	reproduces := func(candidate *Trace) bool {
+		calls++
+		return true // every removal "reproduces", so a naive loop would strip everything
+	}
+
+	m := NewMinimizer(MinimizeBudget{MaxReplays: 1})
+	minimized := m.Minimize(trace, reproduces)
+
+	if calls > 1 {
+		t.Errorf("replay budget of 1 was not respected: saw %d replay attempts", calls)
+	}
+	if len(minimized.Events) == 0 {
+		t.Errorf("budget-exhausted minimize should return the best-effort partial result, not an empty trace")
+	}
+}
+
+func TestMinimizeOnBudgetExhaustionKeepsKnownGoodFieldValues(t *testing.T) {
+	// A trace that only reproduces at its original field values: the first
+	// call (exhausting a MaxReplays budget of 1) must not be mistaken for a
+	// confirmed-good shrink, or shrinkField would hand back 0 instead of the
+	// original, still-reproducing value.
+	trace := &Trace{MaxMessages: 100, CrashQuota: 20, NumberRequests: 50}
+
+	reproduces := func(candidate *Trace) bool {
+		return candidate.MaxMessages == 100 && candidate.CrashQuota == 20 && candidate.NumberRequests == 50
+	}
+
+	m := NewMinimizer(MinimizeBudget{MaxReplays: 1})
+	minimized := m.Minimize(trace, reproduces)
+
+	if minimized.MaxMessages != 100 || minimized.CrashQuota != 20 || minimized.NumberRequests != 50 {
+		t.Fatalf("budget-exhausted shrink returned %+v, want the original known-good values (100, 20, 50)", minimized)
+	}
+}
+
+func TestTraceSaveLoadRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	trace := &Trace{Events: []TraceEvent{ev(1)}, MaxMessages: 3, CrashQuota: 1, NumberRequests: 2}
+
+	path, err := SaveMinimizedTrace(dir, trace)
+	if err != nil {
+		t.Fatalf("SaveMinimizedTrace: %v", err)
+	}
+
+	loaded, err := LoadTrace(path)
+	if err != nil {
+		t.Fatalf("LoadTrace: %v", err)
+	}
+	if loaded.MaxMessages != trace.MaxMessages || len(loaded.Events) != len(trace.Events) {
+		t.Fatalf("round-tripped trace mismatch: got %+v, want %+v", loaded, trace)
+	}
+}
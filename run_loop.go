@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/weng-chenghui/etcd-fuzzing/bandit"
+	"github.com/weng-chenghui/etcd-fuzzing/corpus"
+)
+
+// EpisodeRunner is implemented by Fuzzer: it executes exactly one episode
+// and reports the resulting trace, whether the Checker found a violation,
+// and which guider signals were newly hit. It's the single-episode
+// primitive the run loop needs to drive bandit selection, minimization and
+// corpus seeding from one place instead of Fuzzer.Run looping opaquely.
+type EpisodeRunner interface {
+	RunEpisode() (trace *Trace, violated bool, newSignals []string)
+}
+
+// runEpisode runs one episode of config, picking its Strategy/Mutator/Guider
+// from scheduler when non-nil and feeding the observed gain (new signals per
+// second) back into it so the bandit adapts arm-by-arm.
+func runEpisode(config FuzzerConfig, scheduler *bandit.Scheduler) (trace *Trace, violated bool, newSignals []string, arm *bandit.Arm) {
+	if scheduler != nil {
+		arm = scheduler.Pick()
+		config.Strategy = arm.Strategy.(Strategy)
+		config.Mutator = arm.Mutator.(Mutator)
+		config.Guider = arm.Guider.(Guider)
+	}
+
+	start := time.Now()
+	runner := NewFuzzer(&config)
+	trace, violated, newSignals = runner.RunEpisode()
+	elapsed := time.Since(start)
+
+	if trace != nil {
+		trace.Env = config.RaftEnvironmentConfig
+		trace.Env.Port = 0
+	}
+
+	if scheduler != nil {
+		scheduler.Update(arm, len(newSignals), elapsed)
+	}
+	return trace, violated, newSignals, arm
+}
+
+// reproduces builds a ReproduceFunc that replays a candidate trace through a
+// fresh RaftEnvironment and reports whether it still triggers the same
+// outcome as the original episode: the violation, if one was found, or
+// otherwise that every signal in wantSignals (the specific guider signals
+// the original episode discovered) is still hit. Checking for any new
+// coverage at all, rather than the originally-discovered signals
+// specifically, would let minimization wander toward unrelated coverage
+// instead of shrinking down to what actually matters.
+func reproduces(config FuzzerConfig, wantViolation bool, wantSignals []string) ReproduceFunc {
+	want := make(map[string]bool, len(wantSignals))
+	for _, s := range wantSignals {
+		want[s] = true
+	}
+	return func(candidate *Trace) bool {
+		env := NewRaftEnvironment(config.RaftEnvironmentConfig)
+		violated, newSignals, err := env.Replay(candidate)
+		if err != nil {
+			return false
+		}
+		if wantViolation {
+			return violated
+		}
+		hit := make(map[string]bool, len(newSignals))
+		for _, s := range newSignals {
+			hit[s] = true
+		}
+		for s := range want {
+			if !hit[s] {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// reseedCorpus replays every entry already held in seedCorpus once through a
+// fresh RaftEnvironment before the episode loop starts, reporting the
+// signals each one hits to config.Guider. This warms the guider's coverage
+// state up to where the corpus left it at the end of the previous run, so
+// cross-run resumption doesn't start cold and re-report already-discovered
+// signals as new; config.Corpus (set by FuzzCommand) separately feeds the
+// same entries into the mutation pool.
+func reseedCorpus(config FuzzerConfig, seedCorpus *corpus.Corpus) {
+	if seedCorpus == nil || config.Guider == nil {
+		return
+	}
+	for _, entry := range seedCorpus.Entries() {
+		var trace Trace
+		if err := json.Unmarshal(entry.Data, &trace); err != nil {
+			continue
+		}
+		env := NewRaftEnvironment(config.RaftEnvironmentConfig)
+		if _, newSignals, err := env.Replay(&trace); err == nil {
+			config.Guider.Record(newSignals)
+		}
+	}
+}
+
+// RunFuzzLoop drives config.Budget worth of episodes: each one is handed to
+// the bandit scheduler (when non-nil), any newly-interesting trace is added
+// to seedCorpus with ParentHash set to the entry this loop added just before
+// it (the seed the episode was mutated from is not recorded), and any
+// episode that violates the Checker or lands new
+// coverage is handed to minimizer so a small, readable repro is saved under
+// savePath/minimized instead of the full 50-step trace. Before any of that,
+// seedCorpus is replayed once into config.Guider (see reseedCorpus) so a
+// resumed run doesn't start with cold coverage. It stops early if interrupt
+// fires, mid-episode work is always allowed to finish first, and on the way
+// out it flushes the guider's coverage/trace state to savePath and prints
+// the closing summary line.
+func RunFuzzLoop(config FuzzerConfig, scheduler *bandit.Scheduler, minimizer *Minimizer, seedCorpus *corpus.Corpus, savePath string, interrupt <-chan os.Signal) *BudgetTracker {
+	reseedCorpus(config, seedCorpus)
+
+	tracker := NewBudgetTracker(config.Budget)
+	uniqueSignals := map[string]bool{}
+	lastHash := ""
+
+runLoop:
+	for !tracker.Done() {
+		select {
+		case <-interrupt:
+			break runLoop
+		default:
+		}
+
+		trace, violated, newSignals, _ := runEpisode(config, scheduler)
+		tracker.Tick()
+		for _, s := range newSignals {
+			uniqueSignals[s] = true
+		}
+
+		if seedCorpus != nil && len(newSignals) > 0 {
+			if data, err := json.Marshal(trace); err == nil {
+				entry, err := seedCorpus.Add(data, corpus.Meta{
+					Signals:    newSignals,
+					Episode:    tracker.Episodes(),
+					ParentHash: lastHash,
+				})
+				if err == nil {
+					lastHash = entry.Hash
+				}
+			}
+		}
+
+		if minimizer != nil && (violated || len(newSignals) > 0) {
+			minimized := minimizer.Minimize(trace, reproduces(config, violated, newSignals))
+			if _, err := SaveMinimizedTrace(savePath, minimized); err != nil {
+				fmt.Fprintf(os.Stderr, "run loop: failed to save minimized trace: %v\n", err)
+			}
+		}
+	}
+
+	if config.Guider != nil {
+		config.Guider.Flush(savePath)
+	}
+	fmt.Println(tracker.Summary(len(uniqueSignals)))
+	return tracker
+}
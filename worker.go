@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Worker owns an isolated port and executes WorkItems pulled from a
+// Coordinator's shared queue, reporting back the executed trace, whether it
+// reproduced a violation, and the new guider signals it contributed. It
+// builds a fresh RaftEnvironment for every WorkItem rather than reusing one
+// across its lifetime, matching the fresh-per-replay pattern used everywhere
+// else a trace is run (run_loop.go's reproduces, main.go's ReplayCommand):
+// RaftEnvironment carries state across a Replay (crash counters, term/leader
+// state) that must not leak from one trace into the next.
+type Worker struct {
+	ID        int
+	Port      int
+	envConfig RaftEnvironmentConfig
+	address   string
+	mutateMu  *sync.Mutex
+}
+
+// NewWorker builds a Worker bound to basePort+id, with its own port so
+// workers never contend over the same RaftEnvironment address. mutateMu is
+// shared across every Worker in the pool and serializes calls into
+// WorkItem.Mutator, which isn't safe for concurrent use on its own.
+func NewWorker(id int, basePort int, envConfig RaftEnvironmentConfig, mutateMu *sync.Mutex) *Worker {
+	workerPort := basePort + id
+	envConfig.Port = workerPort
+	return &Worker{
+		ID:        id,
+		Port:      workerPort,
+		envConfig: envConfig,
+		address:   fmt.Sprintf("%s:%d", host, workerPort),
+		mutateMu:  mutateMu,
+	}
+}
+
+// Run pulls WorkItems from work until it's closed, mutating and replaying
+// each seed through a fresh RaftEnvironment, then reporting the executed
+// trace, whether it reproduced a violation, and any newly discovered guider
+// signals on results.
+func (w *Worker) Run(work <-chan WorkItem, results chan<- WorkResult) {
+	for item := range work {
+		trace := item.Seed
+		if item.Mutator != nil {
+			w.mutateMu.Lock()
+			trace = item.Mutator.Mutate(trace)
+			w.mutateMu.Unlock()
+		}
+		trace.Env = w.envConfig
+		trace.Env.Port = 0
+		env := NewRaftEnvironment(w.envConfig)
+		violated, newHits, err := env.Replay(trace)
+		if err != nil {
+			continue
+		}
+		results <- WorkResult{WorkerID: w.ID, Trace: trace, Violated: violated, NewHits: newHits}
+	}
+}